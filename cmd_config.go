@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"brianmargolis.com/audiout/services"
+	"brianmargolis.com/audiout/utils"
+)
+
+// firstExistingConfigPath walks services.ConfigSearchPaths and returns the
+// first one that exists on disk, falling back to the highest-priority path
+// (where `config edit` should create the file) if none do.
+func firstExistingConfigPath(c *cli.Context) string {
+	paths := services.ConfigSearchPaths(c.String("config"))
+	for _, p := range paths {
+		if expanded := utils.ExpandPath(p); fileExists(expanded) {
+			return expanded
+		}
+	}
+	return utils.ExpandPath(paths[0])
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+var configCommand = &cli.Command{
+	Name:  "config",
+	Usage: "manage the audiout config file",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "show",
+			Usage: "print every config file on the search path that exists, in priority order",
+			Action: func(c *cli.Context) error {
+				printed := 0
+				for _, p := range services.ConfigSearchPaths(c.String("config")) {
+					path := utils.ExpandPath(p)
+					b, err := os.ReadFile(path)
+					if err != nil {
+						continue
+					}
+					fmt.Printf("# %s\n%s\n", path, b)
+					printed++
+				}
+				if printed == 0 {
+					fmt.Println("# no config file found; searched:")
+					for _, p := range services.ConfigSearchPaths(c.String("config")) {
+						fmt.Printf("#   %s\n", utils.ExpandPath(p))
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "edit",
+			Usage: "open the highest-priority existing config file (or create one) in $EDITOR",
+			Action: func(c *cli.Context) error {
+				path := firstExistingConfigPath(c)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					return fmt.Errorf("creating config directory: %w", err)
+				}
+				editor := os.Getenv("EDITOR")
+				if editor == "" {
+					editor = "vi"
+				}
+				cmd := exec.Command(editor, path)
+				cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+				return cmd.Run()
+			},
+		},
+		{
+			Name:  "validate",
+			Usage: "validate the config file against the schema and report the offending field path (not line/column)",
+			Action: func(c *cli.Context) error {
+				log, closer, err := constructLogger(c.Bool("verbose"))
+				if err != nil {
+					return fmt.Errorf("logger init failed: %w", err)
+				}
+				defer closer()
+
+				path := firstExistingConfigPath(c)
+				configService := services.NewConfigService(c.String("config"), log)
+				if err := configService.Validate(path); err != nil {
+					return err
+				}
+				fmt.Printf("%s: ok\n", path)
+				return nil
+			},
+		},
+	},
+}
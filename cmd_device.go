@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"brianmargolis.com/audiout/services"
+)
+
+var getCommand = &cli.Command{
+	Name:  "get",
+	Usage: "print the current output device",
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, false)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+
+		current, err := app.audio.Get(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to query current output device: %w", err)
+		}
+		fmt.Println(app.config.FriendlyName(current.ID))
+		return nil
+	},
+}
+
+// deviceJSON is the shape `list --json` prints; it's what `set`'s shell
+// completion and other tooling parse.
+type deviceJSON struct {
+	Friendly   string `json:"friendly"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Transport  string `json:"transport,omitempty"`
+	SampleRate int    `json:"sampleRate,omitempty"`
+}
+
+var listCommand = &cli.Command{
+	Name:  "list",
+	Usage: "list available output devices",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "json", Usage: "print devices as a JSON array instead of friendly\\tid lines"},
+	},
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, false)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+
+		devices, err := app.audio.List(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to list output devices: %w", err)
+		}
+		choices := app.config.BuildChoices(devices, app.log)
+
+		if c.Bool("json") {
+			out := make([]deviceJSON, len(choices))
+			for i, choice := range choices {
+				out[i] = deviceJSON{
+					Friendly:   choice.FriendlyName,
+					ID:         choice.Device.ID,
+					Name:       choice.Device.Name,
+					Transport:  choice.Device.Transport,
+					SampleRate: choice.Device.SampleRate,
+				}
+			}
+			return json.NewEncoder(os.Stdout).Encode(out)
+		}
+
+		for _, choice := range choices {
+			fmt.Printf("%s\t%s\n", choice.FriendlyName, choice.Device.ID)
+		}
+		return nil
+	},
+}
+
+var setCommand = &cli.Command{
+	Name:      "set",
+	Usage:     "switch to the named output device",
+	ArgsUsage: "<name-or-friendly>",
+	Action: func(c *cli.Context) error {
+		target := c.Args().First()
+		if target == "" {
+			return fmt.Errorf("usage: audiout set <name-or-friendly>")
+		}
+
+		app, err := bootstrap(c, false)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+
+		ctx := context.Background()
+		devices, err := app.audio.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list output devices: %w", err)
+		}
+		choice, ok := findChoice(app.config.BuildChoices(devices, app.log), target)
+		if !ok {
+			return fmt.Errorf("no output device matches %q", target)
+		}
+
+		if err := app.audio.Set(ctx, choice.Device.ID); err != nil {
+			return fmt.Errorf("failed to switch output device: %w", err)
+		}
+		services.RecordManualSwitch(app.log)
+		services.Notify(notifyOptionsFrom(c, app.config), choice.FriendlyName, app.log)
+		fmt.Printf("Output -> %s\n", choice.FriendlyName)
+		return nil
+	},
+	// completeDeviceNames shells back out to `audiout list` so completion
+	// always reflects devices actually connected right now.
+	BashComplete: completeDeviceNames,
+}
+
+func completeDeviceNames(c *cli.Context) {
+	out, err := exec.Command(os.Args[0], "list").Output()
+	if err != nil {
+		return
+	}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if friendly, _, ok := strings.Cut(sc.Text(), "\t"); ok {
+			fmt.Println(friendly)
+		}
+	}
+}
+
+var toggleCommand = &cli.Command{
+	Name:  "toggle",
+	Usage: "switch to the next output device alphabetically",
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, false)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+		return selectAndSwitch(c, app, true)
+	},
+}
+
+var pickCommand = &cli.Command{
+	Name:  "pick",
+	Usage: "pick an output device interactively via fzf",
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, true)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+		return selectAndSwitch(c, app, false)
+	},
+}
+
+func pickAction(c *cli.Context) error {
+	return pickCommand.Action(c)
+}
+
+func toggleAction(c *cli.Context) error {
+	return toggleCommand.Action(c)
+}
+
+// selectAndSwitch is shared by the root action, `pick`, and `toggle`: query
+// the current device, list and filter choices, ask the Picker, then switch.
+func selectAndSwitch(c *cli.Context, app *cliApp, toggle bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		app.log.Infow("signal received, cancelling")
+		cancel()
+	}()
+
+	currentDevice, err := app.audio.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query current output device: %w", err)
+	}
+
+	devices, err := app.audio.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list output devices: %w", err)
+	}
+
+	choices := app.config.BuildChoices(devices, app.log)
+	if len(choices) == 0 {
+		return fmt.Errorf("no selectable output devices after filtering")
+	}
+
+	pickerService := services.NewPicker(app.config, app.log)
+	choice, ok, err := pickerService.PickDevice(ctx, choices, currentDevice.ID, toggle)
+	if err != nil {
+		return fmt.Errorf("device selection failed: %w", err)
+	}
+	if !ok {
+		app.log.Infow("no selection; exiting")
+		return nil
+	}
+
+	if err := app.audio.Set(ctx, choice.Device.ID); err != nil {
+		return fmt.Errorf("failed to switch output device: %w", err)
+	}
+	services.RecordManualSwitch(app.log)
+	services.Notify(notifyOptionsFrom(c, app.config), choice.FriendlyName, app.log)
+	fmt.Printf("Output -> %s\n", choice.FriendlyName)
+	return nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"brianmargolis.com/audiout/services"
+)
+
+var serveCommand = &cli.Command{
+	Name:  "serve",
+	Usage: "expose this machine's audio device over HTTP/JSON and advertise it via mDNS",
+	Flags: []cli.Flag{
+		&cli.IntFlag{Name: "port", Value: DEFAULT_SERVE_PORT, Usage: "port to bind the HTTP/JSON API and advertise over mDNS"},
+	},
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, false)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		go func() {
+			for s := range sig {
+				if s == syscall.SIGHUP {
+					// serve only reads DaemonConfig.Nickname once, at
+					// startup, and never consults app.config again, so
+					// there's nothing useful to reload here: restart
+					// `serve` to pick up config changes.
+					app.log.Infow("SIGHUP received; serve doesn't hot-reload, restart to pick up config changes")
+					continue
+				}
+				app.log.Infow("signal received, cancelling")
+				cancel()
+				return
+			}
+		}()
+
+		daemonCfg := services.DaemonConfig{Port: c.Int("port"), Nickname: app.config.Nickname}
+		app.log.Infow("serve starting", "port", c.Int("port"), "nickname", app.config.Nickname)
+		return services.Serve(ctx, app.audio, daemonCfg, app.log)
+	},
+}
+
+var remoteCommand = &cli.Command{
+	Name:  "remote",
+	Usage: "pick an output device from this machine or a peer discovered via mDNS",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "toggle", Aliases: []string{"t"}, Usage: "toggle mode: switch to next device alphabetically"},
+		&cli.DurationFlag{Name: "discover-timeout", Value: 2 * time.Second, Usage: "how long to browse mDNS for peers"},
+	},
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, !c.Bool("toggle"))
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sig
+			app.log.Infow("signal received, cancelling")
+			cancel()
+		}()
+
+		peers, err := services.DiscoverPeers(ctx, c.Duration("discover-timeout"), app.log)
+		if err != nil {
+			return fmt.Errorf("mdns discovery failed: %w", err)
+		}
+		app.log.Infow("peers discovered", "count", len(peers))
+
+		var currentID string
+		if current, err := app.audio.Get(ctx); err != nil {
+			app.log.Warnw("failed to query current output device", "err", err)
+		} else {
+			currentID = current.ID
+		}
+
+		var choices []services.Choice
+		peerByChoiceID := map[string]services.Peer{}
+
+		if devs, err := app.audio.List(ctx); err != nil {
+			app.log.Warnw("failed to list local devices", "err", err)
+		} else {
+			choices = append(choices, app.config.BuildChoices(devs, app.log)...)
+		}
+
+		for _, peer := range peers {
+			host := peer.Nickname
+			if host == "" {
+				host = peer.Host
+			}
+			client := services.NewRemoteAudioDevice(peer, app.log)
+			devs, err := client.List(ctx)
+			if err != nil {
+				app.log.Warnw("peer unreachable", "host", host, "err", err)
+				continue
+			}
+			for _, d := range devs {
+				if app.config.IsIgnoredForHost(d.ID, host) {
+					continue
+				}
+				choiceID := host + ":" + d.ID
+				choices = append(choices, services.Choice{
+					Device:       services.Device{ID: choiceID, Name: d.Name, Transport: d.Transport, SampleRate: d.SampleRate},
+					FriendlyName: fmt.Sprintf("%s (%s)", app.config.FriendlyNameForHost(d.ID, host), host),
+				})
+				peerByChoiceID[choiceID] = peer
+			}
+		}
+
+		if len(choices) == 0 {
+			return fmt.Errorf("no selectable output devices found locally or on the network")
+		}
+
+		pickerService := services.NewPicker(app.config, app.log)
+		choice, ok, err := pickerService.PickDevice(ctx, choices, currentID, c.Bool("toggle"))
+		if err != nil {
+			return fmt.Errorf("device selection failed: %w", err)
+		}
+		if !ok {
+			app.log.Infow("no selection; exiting")
+			return nil
+		}
+
+		if peer, isRemote := peerByChoiceID[choice.Device.ID]; isRemote {
+			_, realID, _ := strings.Cut(choice.Device.ID, ":")
+			client := services.NewRemoteAudioDevice(peer, app.log)
+			if err := client.Set(ctx, realID); err != nil {
+				return fmt.Errorf("failed to switch remote output device: %w", err)
+			}
+		} else if err := app.audio.Set(ctx, choice.Device.ID); err != nil {
+			return fmt.Errorf("failed to switch output device: %w", err)
+		}
+		services.RecordManualSwitch(app.log)
+		services.Notify(notifyOptionsFrom(c, app.config), choice.FriendlyName, app.log)
+		fmt.Printf("Output -> %s\n", choice.FriendlyName)
+		return nil
+	},
+}
+
+var watchCommand = &cli.Command{
+	Name:  "watch",
+	Usage: "apply config priority/rules to hotplug events for as long as this runs",
+	Action: func(c *cli.Context) error {
+		app, err := bootstrap(c, false)
+		if err != nil {
+			return err
+		}
+		defer app.closer()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sig
+			app.log.Infow("signal received, cancelling")
+			cancel()
+		}()
+
+		// AutoSwitcher reads this config on every hotplug event from Run's
+		// goroutine while the loop below can replace it on every config
+		// file change; share it through an atomic.Pointer rather than
+		// mutating *app.config in place so the two never race.
+		var liveConfig atomic.Pointer[services.Config]
+		liveConfig.Store(app.config)
+
+		if reloads, err := app.configService.Subscribe(ctx); err != nil {
+			app.log.Warnw("config hot-reload unavailable", "err", err)
+		} else {
+			go func() {
+				for reloaded := range reloads {
+					app.log.Infow("config changed on disk, reloading", "priority", len(reloaded.Priority), "rules", len(reloaded.Rules))
+					liveConfig.Store(reloaded)
+				}
+			}()
+		}
+
+		switcher := services.NewAutoSwitcher(app.audio, &liveConfig, app.log)
+		app.log.Infow("watch starting", "priority", len(app.config.Priority), "rules", len(app.config.Rules))
+		return switcher.Run(ctx)
+	},
+}
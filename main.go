@@ -1,124 +1,145 @@
 package main
 
 import (
-	"context"
-	"errors"
-	"flag"
 	"fmt"
 	"os"
 	"os/exec"
-	"os/signal"
-	"syscall"
 
+	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 
 	"brianmargolis.com/audiout/services"
-	"brianmargolis.com/audiout/utils"
 )
 
-const DEFAULT_CONFIG_PATH = "~/.config/.audiout.yaml"
-const CONFIG_PATH_ENV = "AUDIOUT_CONFIG"
+const BACKEND_ENV = "AUDIOUT_BACKEND"
+const DEFAULT_SERVE_PORT = 7376
 
-func main() {
-	verbose, toggle, err := parseArgs()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "arg parse failed: %v\n", err)
-		os.Exit(1)
-	}
+// backendFlag is shared by every command that talks to an AudioDevice.
+// Global flags must precede the subcommand name, e.g. `audiout --backend
+// pactl get`.
+var backendFlag = &cli.StringFlag{
+	Name:    "backend",
+	EnvVars: []string{BACKEND_ENV},
+	Usage:   "audio backend to use (switchaudio, coreaudio, pactl, wpctl); defaults to the platform default",
+}
 
-	log, logCloser, err := constructLogger(verbose)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "logger init failed: %v\n", err)
-		os.Exit(1)
-	}
-	defer logCloser()
+var verboseFlag = &cli.BoolFlag{
+	Name:    "verbose",
+	Aliases: []string{"v"},
+	Usage:   "verbose logging",
+}
 
-	// config path
-	cfgPath := os.Getenv(CONFIG_PATH_ENV)
-	if cfgPath == "" {
-		cfgPath = DEFAULT_CONFIG_PATH
-	}
+// configPathFlag lets the user point at an explicit config file; urfave
+// already falls back to ConfigPathEnv ($AUDIOUT_CONFIG) when unset, so the
+// search order in services.ConfigSearchPaths only has to handle the rest.
+var configPathFlag = &cli.StringFlag{
+	Name:    "config",
+	EnvVars: []string{services.ConfigPathEnv},
+	Usage:   "explicit config file path; see `audiout config show` for the full search order",
+}
 
-	log.Infow("start", "verbose", verbose, "config", cfgPath)
-
-	// listen for interrupts
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sig
-		log.Infow("signal received, cancelling")
-		cancel()
-	}()
-
-	if err := checkDependencies(log); err != nil {
-		log.Errorw("dependency check failed", "err", err)
+// generateFishCompletionFlag mirrors urfave/cli's built-in
+// --generate-bash-completion: completions/audiout.fish pipes this flag's
+// output straight into `source`, the way upstream urfave/cli docs recommend
+// (`your-app --generate-fish-completion | source`).
+var generateFishCompletionFlag = &cli.BoolFlag{
+	Name:   "generate-fish-completion",
+	Hidden: true,
+}
+
+func main() {
+	var app *cli.App
+	app = &cli.App{
+		Name:                 "audiout",
+		Usage:                "switch audio output devices",
+		EnableBashCompletion: true,
+		Flags: []cli.Flag{
+			verboseFlag,
+			backendFlag,
+			configPathFlag,
+			generateFishCompletionFlag,
+			// kept for backward compatibility with the pre-urfave CLI;
+			// `audiout -t` is now equivalent to `audiout toggle`.
+			&cli.BoolFlag{Name: "toggle", Aliases: []string{"t"}, Usage: "deprecated: same as running the toggle command"},
+			&cli.BoolFlag{Name: "quiet", Usage: "suppress desktop notification and confirmation sound on switch"},
+			&cli.BoolFlag{Name: "notify", Usage: "force the desktop notification on, even if config disables it"},
+		},
+		Before: func(c *cli.Context) error {
+			if c.Bool("generate-fish-completion") {
+				completion, err := app.ToFishCompletion()
+				if err != nil {
+					return fmt.Errorf("generating fish completion: %w", err)
+				}
+				fmt.Println(completion)
+				os.Exit(0)
+			}
+			return nil
+		},
+		Action: func(c *cli.Context) error {
+			if c.Bool("toggle") {
+				return toggleAction(c)
+			}
+			return pickAction(c)
+		},
+		Commands: []*cli.Command{
+			getCommand,
+			listCommand,
+			setCommand,
+			toggleCommand,
+			pickCommand,
+			watchCommand,
+			serveCommand,
+			remoteCommand,
+			configCommand,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	log.Debug("all dependencies present")
+}
 
-	config, err := loadConfig(cfgPath, log)
-	if err != nil {
-		log.Errorw("config load failed (continuing with defaults)", "path", cfgPath, "err", err)
-		config = &services.Config{}
-	}
-	log.Infow("config loaded", "config", config, "toggle", toggle)
+// -------- shared bootstrap --------
 
-	audioDeviceService := services.NewAudioDevice(log)
-	pickerService := services.NewPicker(config, log)
+// cliApp bundles the state every command's Action needs: a logger, the
+// config service and its resolved config, and the selected audio backend.
+type cliApp struct {
+	log           *zap.SugaredLogger
+	closer        func() error
+	configService services.ConfigService
+	config        *services.Config
+	audio         services.AudioDevice
+}
 
-	currentDevice, err := audioDeviceService.Get(ctx)
+// bootstrap constructs the logger, config, and audio backend for a command,
+// in that order, and verifies the backend's dependencies are present.
+// needsFzf should be true only for commands that actually invoke the fzf
+// picker (pick, and remote without --toggle) — get/list/set/toggle/watch/
+// serve never shell out to it and shouldn't fail to start without it.
+func bootstrap(c *cli.Context, needsFzf bool) (*cliApp, error) {
+	log, closer, err := constructLogger(c.Bool("verbose"))
 	if err != nil {
-		log.Errorw("failed to query current output device", "err", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("logger init failed: %w", err)
 	}
-	log.Infow("current device", "currentDevice", currentDevice)
 
-	devices, err := audioDeviceService.List(ctx)
+	configService := services.NewConfigService(c.String("config"), log)
+	config, err := configService.Load()
 	if err != nil {
-		log.Errorw("failed to list output devices", "err", err)
-		os.Exit(1)
+		log.Errorw("config load failed (continuing with best-effort result)", "err", err)
 	}
-	log.Infow("devices found (pre-filter)", "count", len(devices))
 
-	// ----- build choices -----
-	choices := buildChoices(devices, config, log)
-	if len(choices) == 0 {
-		log.Error("no selectable output devices after filtering")
-		os.Exit(1)
-	}
-	log.Infow("choices (post-filter)", "count", len(choices))
-
-	// ----- pick device -----
-	choice, ok, err := pickerService.PickDevice(ctx, choices, currentDevice, toggle)
+	audio, err := services.NewAudioDevice(c.String("backend"), log)
 	if err != nil {
-		log.Errorw("device selection failed", "err", err)
-		os.Exit(1)
+		closer()
+		return nil, fmt.Errorf("backend selection failed: %w", err)
 	}
-	if !ok {
-		log.Infow("no selection; exiting")
-		return
-	}
-	log.Infow("selected", "friendly", choice.FriendlyName, "real", choice.RealName)
-
-	// ----- switch -----
-	if err := audioDeviceService.Set(ctx, choice.RealName); err != nil {
-		log.Errorw("failed to switch output device", "target", choice.RealName, "err", err)
-		os.Exit(1)
+	if err := checkDependencies(log, audio, needsFzf); err != nil {
+		closer()
+		return nil, fmt.Errorf("dependency check failed: %w", err)
 	}
-	log.Infow("switched", "to", choice.FriendlyName)
-	fmt.Printf("Output -> %s\n", choice.FriendlyName)
-}
 
-// -------- arg parsing and logging --------
-func parseArgs() (bool, bool, error) {
-	var verbose, toggle bool
-	flag.BoolVar(&verbose, "v", false, "verbose logging")
-	flag.BoolVar(&toggle, "t", false, "toggle mode: switch to next audio device alphabetically")
-	flag.Parse()
-	return verbose, toggle, nil
+	return &cliApp{log: log, closer: closer, configService: configService, config: config, audio: audio}, nil
 }
 
 func constructLogger(verbose bool) (
@@ -144,15 +165,19 @@ func constructLogger(verbose bool) (
 }
 
 // -------- dependencies --------
-func checkDependencies(log *zap.SugaredLogger) error {
-	if err := requireBinary("SwitchAudioSource"); err != nil {
-		return fmt.Errorf("missing dependency: SwitchAudioSource (hint: brew install switchaudio-osx): %w", err)
+func checkDependencies(log *zap.SugaredLogger, backend services.AudioDevice, needsFzf bool) error {
+	for _, dep := range backend.Dependencies() {
+		if err := requireBinary(dep); err != nil {
+			return fmt.Errorf("missing dependency: %s: %w", dep, err)
+		}
+		log.Debugw("ok: dependency present", "dep", dep)
 	}
-	log.Debug("ok: SwitchAudioSource present")
-	if err := requireBinary("fzf"); err != nil {
-		return fmt.Errorf("missing dependency: fzf (hint: brew install fzf): %w", err)
+	if needsFzf {
+		if err := requireBinary("fzf"); err != nil {
+			return fmt.Errorf("missing dependency: fzf (hint: brew install fzf): %w", err)
+		}
+		log.Debug("ok: fzf present")
 	}
-	log.Debug("ok: fzf present")
 	return nil
 }
 
@@ -161,59 +186,29 @@ func requireBinary(name string) error {
 	return err
 }
 
-// -------- config --------
-func loadConfig(path string, log *zap.SugaredLogger) (*services.Config, error) {
-	path = utils.ExpandPath(path)
-	b, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			log.Infow("config not found; using defaults", "path", path)
-			return &services.Config{}, nil
-		}
-		return nil, err
-	}
-	var c services.Config
-	if err := yaml.Unmarshal(b, &c); err != nil {
-		return &services.Config{
-			FriendlyNames: map[string]string{},
-		}, err
-
-	}
-	if c.FriendlyNames == nil {
-		c.FriendlyNames = map[string]string{}
-	}
-	return &c, nil
-}
-
-// -------- config helpers --------
+// -------- notification helpers --------
 
-func isIgnored(name string, config *services.Config) bool {
-	for _, n := range config.Ignored {
-		if name == n {
-			return true
-		}
+// notifyOptionsFrom applies --quiet/--notify on top of the config's
+// notify/sound settings: --quiet wins over everything, --notify forces the
+// desktop notification on.
+func notifyOptionsFrom(c *cli.Context, config *services.Config) services.NotifyOptions {
+	notify := config.NotifyEnabled()
+	sound := config.SoundEnabled()
+	if c.Bool("notify") {
+		notify = true
 	}
-	return false
-}
-
-func friendlyOf(real string, config *services.Config) string {
-	if f, ok := config.FriendlyNames[real]; ok && f != "" {
-		return f
+	if c.Bool("quiet") {
+		notify = false
+		sound = false
 	}
-	return real
+	return services.NotifyOptions{Notify: notify, Sound: sound, SoundFile: config.SoundFile}
 }
 
-func buildChoices(devices []string, config *services.Config, log *zap.SugaredLogger) []services.Choice {
-	var choices []services.Choice
-	for _, device := range devices {
-		if isIgnored(device, config) {
-			log.Debugw("ignored device", "name", device)
-			continue
+func findChoice(choices []services.Choice, target string) (services.Choice, bool) {
+	for _, choice := range choices {
+		if choice.FriendlyName == target || choice.Device.ID == target || choice.Device.Name == target {
+			return choice, true
 		}
-		choices = append(choices, services.Choice{
-			FriendlyName: friendlyOf(device, config),
-			RealName:     device,
-		})
 	}
-	return choices
+	return services.Choice{}, false
 }
@@ -1,63 +1,99 @@
 package services
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
-// AudioDevice handles all audio device operations
-type AudioDevice interface {
-	Get(ctx context.Context) (string, error)
-	List(ctx context.Context) ([]string, error)
-	Set(ctx context.Context, deviceName string) error
+// Device describes a single audio output device as reported by a backend.
+// Transport and SampleRate are best-effort: backends that cannot determine
+// them leave the field at its zero value.
+type Device struct {
+	ID         string
+	Name       string
+	Transport  string
+	SampleRate int
 }
 
-type audioDevice struct {
-	log *zap.SugaredLogger
+// DeviceEventType distinguishes the two kinds of hotplug event a backend's
+// Watch can emit.
+type DeviceEventType int
+
+const (
+	DeviceAdded DeviceEventType = iota
+	DeviceRemoved
+)
+
+// DeviceEvent is emitted by AudioDevice.Watch when a device is plugged in or
+// unplugged.
+type DeviceEvent struct {
+	Type   DeviceEventType
+	Device Device
 }
 
-func NewAudioDevice(log *zap.SugaredLogger) AudioDevice {
-	return &audioDevice{log: log}
+// DefaultWatchInterval is the polling interval PollWatch uses for backends
+// with no native hotplug push mechanism.
+const DefaultWatchInterval = 2 * time.Second
+
+// AudioDevice handles all audio device operations for a single backend.
+type AudioDevice interface {
+	Get(ctx context.Context) (Device, error)
+	List(ctx context.Context) ([]Device, error)
+	Set(ctx context.Context, id string) error
+
+	// Watch emits Added/Removed events as devices are plugged and unplugged.
+	// The returned channel is closed when ctx is cancelled.
+	Watch(ctx context.Context) (<-chan DeviceEvent, error)
+
+	// Dependencies lists the external binaries or libraries this backend
+	// needs present on the host in order to function. checkDependencies in
+	// main.go verifies these before the backend is used.
+	Dependencies() []string
 }
 
-func (s *audioDevice) Get(ctx context.Context) (string, error) {
-	out, err := runCmd(ctx, "SwitchAudioSource", "-c", "-t", "output")
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(out), nil
+// BackendFactory constructs a backend's AudioDevice implementation.
+type BackendFactory func(log *zap.SugaredLogger) AudioDevice
+
+var backends = map[string]BackendFactory{}
+
+// RegisterBackend makes a backend available by name for NewAudioDevice.
+// Backend implementations call this from an init() function.
+func RegisterBackend(name string, factory BackendFactory) {
+	backends[name] = factory
 }
 
-func (s *audioDevice) List(ctx context.Context) ([]string, error) {
-	out, err := runCmd(ctx, "SwitchAudioSource", "-a", "-t", "output")
-	if err != nil {
-		return nil, err
+// NewAudioDevice constructs the AudioDevice implementation for the named
+// backend. An empty name selects DefaultBackend, which is platform-specific
+// (see backend_default_*.go).
+func NewAudioDevice(name string, log *zap.SugaredLogger) (AudioDevice, error) {
+	if name == "" {
+		name = DefaultBackend
 	}
-	var devs []string
-	sc := bufio.NewScanner(strings.NewReader(out))
-	for sc.Scan() {
-		name := strings.TrimSpace(sc.Text())
-		if name != "" {
-			devs = append(devs, name)
-		}
-	}
-	if err := sc.Err(); err != nil {
-		return nil, err
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown audio backend %q (available: %s)", name, strings.Join(AvailableBackends(), ", "))
 	}
-	return devs, nil
+	return factory(log), nil
 }
 
-func (s *audioDevice) Set(ctx context.Context, deviceName string) error {
-	_, err := runCmd(ctx, "SwitchAudioSource", "-s", deviceName, "-t", "output")
-	return err
+// AvailableBackends lists the names of backends registered for the current
+// build (registration happens via build-tagged init() functions, so this
+// varies by platform).
+func AvailableBackends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	return names
 }
 
+// runCmd is shared by the shell-out backends (switchaudio, pactl, wpctl).
 func runCmd(ctx context.Context, name string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
 	var outBuf, errBuf bytes.Buffer
@@ -75,3 +111,64 @@ func runCmd(ctx context.Context, name string, args ...string) (string, error) {
 	}
 	return outBuf.String(), nil
 }
+
+// PollWatch implements Watch for backends with no native hotplug push
+// mechanism: it polls list on the given interval and diffs successive
+// results by Device.ID, emitting Added/Removed events for what changed.
+func PollWatch(ctx context.Context, interval time.Duration, list func(context.Context) ([]Device, error)) (<-chan DeviceEvent, error) {
+	events := make(chan DeviceEvent)
+	go func() {
+		defer close(events)
+		seen := map[string]Device{}
+		if devs, err := list(ctx); err == nil {
+			for _, d := range devs {
+				seen[d.ID] = d
+			}
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				devs, err := list(ctx)
+				if err != nil {
+					continue
+				}
+				seen = diffDevices(ctx, events, seen, devs)
+				if seen == nil {
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// diffDevices compares seen against the latest list, sends Added/Removed
+// events for the difference, and returns the new seen set. It returns nil if
+// ctx was cancelled while sending.
+func diffDevices(ctx context.Context, events chan<- DeviceEvent, seen map[string]Device, devs []Device) map[string]Device {
+	current := make(map[string]Device, len(devs))
+	for _, d := range devs {
+		current[d.ID] = d
+		if _, ok := seen[d.ID]; !ok {
+			select {
+			case events <- DeviceEvent{Type: DeviceAdded, Device: d}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+	for id, d := range seen {
+		if _, ok := current[id]; !ok {
+			select {
+			case events <- DeviceEvent{Type: DeviceRemoved, Device: d}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+	return current
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultDebounce is how long AutoSwitcher holds off after a switch before
+// reacting to further hotplug events, so it doesn't flap or fight a switch
+// the user just made.
+const DefaultDebounce = 5 * time.Second
+
+// AutoSwitcher consumes an AudioDevice's hotplug events and applies
+// config.Priority/config.Rules to keep the output device on the desired
+// one — the same "apply desired state" pattern a config-reconciliation
+// daemon uses, scoped to audio outputs.
+//
+// config is an atomic.Pointer rather than a plain *Config because
+// `audiout watch` swaps it out from a ConfigService.Subscribe goroutine
+// concurrently with Run reading it on every hotplug event.
+type AutoSwitcher struct {
+	audio    AudioDevice
+	config   *atomic.Pointer[Config]
+	log      *zap.SugaredLogger
+	debounce time.Duration
+
+	lastSwitch time.Time
+}
+
+func NewAutoSwitcher(audio AudioDevice, config *atomic.Pointer[Config], log *zap.SugaredLogger) *AutoSwitcher {
+	return &AutoSwitcher{
+		audio:    audio,
+		config:   config,
+		log:      log,
+		debounce: DefaultDebounce,
+	}
+}
+
+// NoteManualSet records that the output device was just switched by hand
+// in this process, so Run won't immediately reverse it inside the debounce
+// window. `audiout set`/`pick`/`toggle` run as separate one-shot processes
+// from `audiout watch`, so they can't call this directly — they instead
+// call RecordManualSwitch, which handle() also checks.
+func (a *AutoSwitcher) NoteManualSet() {
+	a.lastSwitch = time.Now()
+}
+
+// Run consumes hotplug events from audio.Watch and applies rules until ctx
+// is cancelled or the event channel closes.
+func (a *AutoSwitcher) Run(ctx context.Context) error {
+	events, err := a.audio.Watch(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case evt, ok := <-events:
+			if !ok {
+				return nil
+			}
+			a.handle(ctx, evt)
+		}
+	}
+}
+
+func (a *AutoSwitcher) handle(ctx context.Context, evt DeviceEvent) {
+	if time.Since(a.lastSwitch) < a.debounce {
+		a.log.Debugw("auto-switch: inside debounce window, ignoring event", "device", evt.Device.Name)
+		return
+	}
+	if since := time.Since(lastManualSwitch()); since < a.debounce {
+		a.log.Debugw("auto-switch: inside debounce window after a manual switch, ignoring event", "device", evt.Device.Name)
+		return
+	}
+	config := a.config.Load()
+	if config.IsIgnored(evt.Device.ID) {
+		return
+	}
+
+	for _, rule := range config.Rules {
+		switch {
+		case evt.Type == DeviceAdded && matchName(rule.WhenConnected, evt.Device.Name):
+			a.switchTo(ctx, rule.SwitchTo)
+			return
+		case evt.Type == DeviceRemoved && matchName(rule.WhenDisconnected, evt.Device.Name):
+			if rule.SwitchToHighestAvailable {
+				a.switchToHighestPriority(ctx)
+			} else if rule.SwitchTo != "" {
+				a.switchTo(ctx, rule.SwitchTo)
+			}
+			return
+		}
+	}
+}
+
+func (a *AutoSwitcher) switchTo(ctx context.Context, target string) {
+	devs, err := a.audio.List(ctx)
+	if err != nil {
+		a.log.Warnw("auto-switch: failed to list devices", "err", err)
+		return
+	}
+	for _, d := range devs {
+		if matchName(target, d.Name) {
+			a.set(ctx, d)
+			return
+		}
+	}
+	a.log.Debugw("auto-switch: no connected device matches rule target", "target", target)
+}
+
+// switchToHighestPriority walks config.Priority in order and switches to the
+// first connected, non-ignored device that matches an entry.
+func (a *AutoSwitcher) switchToHighestPriority(ctx context.Context) {
+	devs, err := a.audio.List(ctx)
+	if err != nil {
+		a.log.Warnw("auto-switch: failed to list devices", "err", err)
+		return
+	}
+	config := a.config.Load()
+	for _, entry := range config.Priority {
+		for _, d := range devs {
+			if config.IsIgnored(d.ID) {
+				continue
+			}
+			if matchName(entry.Match, d.Name) {
+				a.set(ctx, d)
+				return
+			}
+		}
+	}
+	a.log.Debugw("auto-switch: no connected device matches any priority entry")
+}
+
+func (a *AutoSwitcher) set(ctx context.Context, d Device) {
+	if err := a.audio.Set(ctx, d.ID); err != nil {
+		a.log.Errorw("auto-switch: failed to switch output device", "target", d.Name, "err", err)
+		return
+	}
+	a.lastSwitch = time.Now()
+	a.log.Infow("auto-switch: switched", "to", d.Name)
+}
+
+func matchName(pattern, name string) bool {
+	if pattern == "" {
+		return false
+	}
+	ok, err := filepath.Match(pattern, name)
+	return err == nil && ok
+}
@@ -0,0 +1,250 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo LDFLAGS: -framework CoreAudio
+#include <CoreAudio/CoreAudio.h>
+#include <stdlib.h>
+
+static OSStatus getDefaultOutputDevice(AudioObjectID *outID) {
+	AudioObjectPropertyAddress addr = {
+		kAudioHardwarePropertyDefaultOutputDevice,
+		kAudioObjectPropertyScopeGlobal,
+		kAudioObjectPropertyElementMain,
+	};
+	UInt32 size = sizeof(AudioObjectID);
+	return AudioObjectGetPropertyData(kAudioObjectSystemObject, &addr, 0, NULL, &size, outID);
+}
+
+static OSStatus setDefaultOutputDevice(AudioObjectID id) {
+	AudioObjectPropertyAddress addr = {
+		kAudioHardwarePropertyDefaultOutputDevice,
+		kAudioObjectPropertyScopeGlobal,
+		kAudioObjectPropertyElementMain,
+	};
+	UInt32 size = sizeof(AudioObjectID);
+	return AudioObjectSetPropertyData(kAudioObjectSystemObject, &addr, 0, NULL, size, &id);
+}
+
+static OSStatus getOutputDeviceList(AudioObjectID **outDevices, UInt32 *outCount) {
+	AudioObjectPropertyAddress addr = {
+		kAudioHardwarePropertyDevices,
+		kAudioObjectPropertyScopeGlobal,
+		kAudioObjectPropertyElementMain,
+	};
+	UInt32 size = 0;
+	OSStatus status = AudioObjectGetPropertyDataSize(kAudioObjectSystemObject, &addr, 0, NULL, &size);
+	if (status != noErr) {
+		return status;
+	}
+	AudioObjectID *devices = (AudioObjectID *)malloc(size);
+	status = AudioObjectGetPropertyData(kAudioObjectSystemObject, &addr, 0, NULL, &size, devices);
+	if (status != noErr) {
+		free(devices);
+		return status;
+	}
+	*outDevices = devices;
+	*outCount = size / sizeof(AudioObjectID);
+	return noErr;
+}
+
+static UInt32 deviceOutputChannelCount(AudioObjectID id) {
+	AudioObjectPropertyAddress addr = {
+		kAudioDevicePropertyStreamConfiguration,
+		kAudioDevicePropertyScopeOutput,
+		kAudioObjectPropertyElementMain,
+	};
+	UInt32 size = 0;
+	if (AudioObjectGetPropertyDataSize(id, &addr, 0, NULL, &size) != noErr || size == 0) {
+		return 0;
+	}
+	AudioBufferList *list = (AudioBufferList *)malloc(size);
+	if (AudioObjectGetPropertyData(id, &addr, 0, NULL, &size, list) != noErr) {
+		free(list);
+		return 0;
+	}
+	UInt32 channels = 0;
+	for (UInt32 i = 0; i < list->mNumberBuffers; i++) {
+		channels += list->mBuffers[i].mNumberChannels;
+	}
+	free(list);
+	return channels;
+}
+
+static Float64 deviceSampleRate(AudioObjectID id) {
+	AudioObjectPropertyAddress addr = {
+		kAudioDevicePropertyNominalSampleRate,
+		kAudioObjectPropertyScopeGlobal,
+		kAudioObjectPropertyElementMain,
+	};
+	Float64 rate = 0;
+	UInt32 size = sizeof(rate);
+	AudioObjectGetPropertyData(id, &addr, 0, NULL, &size, &rate);
+	return rate;
+}
+
+static UInt32 deviceTransportType(AudioObjectID id) {
+	AudioObjectPropertyAddress addr = {
+		kAudioDevicePropertyTransportType,
+		kAudioObjectPropertyScopeGlobal,
+		kAudioObjectPropertyElementMain,
+	};
+	UInt32 transport = 0;
+	UInt32 size = sizeof(transport);
+	AudioObjectGetPropertyData(id, &addr, 0, NULL, &size, &transport);
+	return transport;
+}
+
+static CFStringRef deviceNameRef(AudioObjectID id) {
+	AudioObjectPropertyAddress addr = {
+		kAudioObjectPropertyName,
+		kAudioObjectPropertyScopeGlobal,
+		kAudioObjectPropertyElementMain,
+	};
+	CFStringRef name = NULL;
+	UInt32 size = sizeof(name);
+	AudioObjectGetPropertyData(id, &addr, 0, NULL, &size, &name);
+	return name;
+}
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	RegisterBackend("coreaudio", func(log *zap.SugaredLogger) AudioDevice {
+		return &coreAudioBackend{log: log}
+	})
+}
+
+// coreAudioBackend talks to CoreAudio directly via cgo
+// (AudioObjectGetPropertyData / kAudioHardwarePropertyDefaultOutputDevice),
+// avoiding the SwitchAudioSource dependency of the switchaudio backend.
+type coreAudioBackend struct {
+	log *zap.SugaredLogger
+}
+
+func (c *coreAudioBackend) Dependencies() []string {
+	// CoreAudio ships with the OS; nothing to install.
+	return nil
+}
+
+func (c *coreAudioBackend) Get(ctx context.Context) (Device, error) {
+	var id C.AudioObjectID
+	if status := C.getDefaultOutputDevice(&id); status != 0 {
+		return Device{}, fmt.Errorf("coreaudio: get default output device: status %d", int(status))
+	}
+	return c.describe(id)
+}
+
+func (c *coreAudioBackend) List(ctx context.Context) ([]Device, error) {
+	ids, err := c.outputDeviceIDs()
+	if err != nil {
+		return nil, err
+	}
+	devs := make([]Device, 0, len(ids))
+	for _, id := range ids {
+		d, err := c.describe(id)
+		if err != nil {
+			c.log.Debugw("skipping coreaudio device", "id", uint32(id), "err", err)
+			continue
+		}
+		devs = append(devs, d)
+	}
+	return devs, nil
+}
+
+func (c *coreAudioBackend) Set(ctx context.Context, id string) error {
+	ids, err := c.outputDeviceIDs()
+	if err != nil {
+		return err
+	}
+	for _, devID := range ids {
+		d, err := c.describe(devID)
+		if err != nil {
+			continue
+		}
+		if d.ID == id || d.Name == id {
+			if status := C.setDefaultOutputDevice(devID); status != 0 {
+				return fmt.Errorf("coreaudio: set default output device: status %d", int(status))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("coreaudio: no output device matching %q", id)
+}
+
+func (c *coreAudioBackend) Watch(ctx context.Context) (<-chan DeviceEvent, error) {
+	return PollWatch(ctx, DefaultWatchInterval, c.List)
+}
+
+func (c *coreAudioBackend) outputDeviceIDs() ([]C.AudioObjectID, error) {
+	var cDevices *C.AudioObjectID
+	var count C.UInt32
+	if status := C.getOutputDeviceList(&cDevices, &count); status != 0 {
+		return nil, fmt.Errorf("coreaudio: list devices: status %d", int(status))
+	}
+	defer C.free(unsafe.Pointer(cDevices))
+
+	all := unsafe.Slice(cDevices, int(count))
+	var ids []C.AudioObjectID
+	for _, id := range all {
+		if C.deviceOutputChannelCount(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (c *coreAudioBackend) describe(id C.AudioObjectID) (Device, error) {
+	nameRef := C.deviceNameRef(id)
+	if nameRef == 0 {
+		return Device{}, fmt.Errorf("coreaudio: device %d has no name", uint32(id))
+	}
+	defer C.CFRelease(C.CFTypeRef(nameRef))
+	name := cfStringToGo(nameRef)
+
+	return Device{
+		ID:         strconv.FormatUint(uint64(id), 10),
+		Name:       name,
+		Transport:  transportName(uint32(C.deviceTransportType(id))),
+		SampleRate: int(C.deviceSampleRate(id)),
+	}, nil
+}
+
+func cfStringToGo(ref C.CFStringRef) string {
+	length := C.CFStringGetLength(ref)
+	maxSize := C.CFStringGetMaximumSizeForEncoding(length, C.kCFStringEncodingUTF8) + 1
+	buf := make([]byte, int(maxSize))
+	ok := C.CFStringGetCString(ref, (*C.char)(unsafe.Pointer(&buf[0])), maxSize, C.kCFStringEncodingUTF8)
+	if ok == 0 {
+		return ""
+	}
+	return strings.TrimRight(string(buf), "\x00")
+}
+
+func transportName(transport uint32) string {
+	switch transport {
+	case uint32(C.kAudioDeviceTransportTypeBuiltIn):
+		return "builtin"
+	case uint32(C.kAudioDeviceTransportTypeBluetooth), uint32(C.kAudioDeviceTransportTypeBluetoothLE):
+		return "bluetooth"
+	case uint32(C.kAudioDeviceTransportTypeUSB):
+		return "usb"
+	case uint32(C.kAudioDeviceTransportTypeHDMI):
+		return "hdmi"
+	case uint32(C.kAudioDeviceTransportTypeAirPlay):
+		return "airplay"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,8 @@
+//go:build darwin
+
+package services
+
+// DefaultBackend is used when no --backend flag or AUDIOUT_BACKEND env var
+// is set. switchaudio remains the default for backward compatibility with
+// existing configs; pass --backend coreaudio to use the cgo implementation.
+const DefaultBackend = "switchaudio"
@@ -0,0 +1,7 @@
+//go:build linux
+
+package services
+
+// DefaultBackend is used when no --backend flag or AUDIOUT_BACKEND env var
+// is set.
+const DefaultBackend = "pactl"
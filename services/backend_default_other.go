@@ -0,0 +1,8 @@
+//go:build !darwin && !linux
+
+package services
+
+// DefaultBackend is used when no --backend flag or AUDIOUT_BACKEND env var
+// is set. Unsupported platforms have no registered backends, so NewAudioDevice
+// will return an error naming this default until one is added.
+const DefaultBackend = "switchaudio"
@@ -0,0 +1,251 @@
+//go:build linux
+
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	RegisterBackend("pactl", func(log *zap.SugaredLogger) AudioDevice {
+		return &pactlBackend{log: log}
+	})
+	RegisterBackend("wpctl", func(log *zap.SugaredLogger) AudioDevice {
+		return &wpctlBackend{log: log}
+	})
+}
+
+// pactlBackend drives PulseAudio (or PipeWire's pulse shim) via pactl.
+type pactlBackend struct {
+	log *zap.SugaredLogger
+}
+
+func (p *pactlBackend) Dependencies() []string {
+	return []string{"pactl"}
+}
+
+func (p *pactlBackend) Get(ctx context.Context) (Device, error) {
+	out, err := runCmd(ctx, "pactl", "get-default-sink")
+	if err != nil {
+		return Device{}, err
+	}
+	id := strings.TrimSpace(out)
+	devs, err := p.List(ctx)
+	if err != nil {
+		return Device{}, err
+	}
+	for _, d := range devs {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return Device{ID: id, Name: id, Transport: "pulseaudio"}, nil
+}
+
+func (p *pactlBackend) List(ctx context.Context) ([]Device, error) {
+	out, err := runCmd(ctx, "pactl", "list", "sinks")
+	if err != nil {
+		return nil, err
+	}
+	return parsePactlSinks(out), nil
+}
+
+func (p *pactlBackend) Set(ctx context.Context, id string) error {
+	_, err := runCmd(ctx, "pactl", "set-default-sink", id)
+	return err
+}
+
+// Watch uses `pactl subscribe` rather than polling: it re-lists sinks each
+// time PulseAudio reports a sink event and diffs the result.
+func (p *pactlBackend) Watch(ctx context.Context) (<-chan DeviceEvent, error) {
+	cmd := exec.CommandContext(ctx, "pactl", "subscribe")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("pactl watch: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("pactl watch: %w", err)
+	}
+
+	events := make(chan DeviceEvent)
+	go func() {
+		defer close(events)
+		defer cmd.Wait()
+
+		seen := map[string]Device{}
+		if devs, err := p.List(ctx); err == nil {
+			for _, d := range devs {
+				seen[d.ID] = d
+			}
+		}
+
+		sc := bufio.NewScanner(stdout)
+		for sc.Scan() {
+			if !strings.Contains(sc.Text(), "on sink") {
+				continue
+			}
+			devs, err := p.List(ctx)
+			if err != nil {
+				p.log.Debugw("pactl watch: list failed", "err", err)
+				continue
+			}
+			seen = diffDevices(ctx, events, seen, devs)
+			if seen == nil {
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func parsePactlSinks(out string) []Device {
+	var devs []Device
+	var cur *Device
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "Sink #"):
+			if cur != nil {
+				devs = append(devs, *cur)
+			}
+			cur = &Device{Transport: "pulseaudio"}
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "Name:"):
+			cur.ID = strings.TrimSpace(strings.TrimPrefix(line, "Name:"))
+			if cur.Name == "" {
+				cur.Name = cur.ID
+			}
+		case strings.HasPrefix(line, "Description:"):
+			cur.Name = strings.TrimSpace(strings.TrimPrefix(line, "Description:"))
+		case strings.HasPrefix(line, "Sample Specification:"):
+			cur.SampleRate = parsePulseSampleRate(line)
+		}
+	}
+	if cur != nil {
+		devs = append(devs, *cur)
+	}
+	return devs
+}
+
+// parsePulseSampleRate pulls the Hz figure out of a line like:
+// "Sample Specification: s16le 2ch 48000Hz"
+func parsePulseSampleRate(line string) int {
+	fields := strings.Fields(line)
+	for _, f := range fields {
+		if strings.HasSuffix(f, "Hz") {
+			if hz, err := strconv.Atoi(strings.TrimSuffix(f, "Hz")); err == nil {
+				return hz
+			}
+		}
+	}
+	return 0
+}
+
+// wpctlBackend drives PipeWire directly via wpctl, for setups without the
+// pulse compatibility layer.
+type wpctlBackend struct {
+	log *zap.SugaredLogger
+}
+
+func (w *wpctlBackend) Dependencies() []string {
+	return []string{"wpctl"}
+}
+
+func (w *wpctlBackend) Get(ctx context.Context) (Device, error) {
+	devs, defaultID, err := w.listWithDefault(ctx)
+	if err != nil {
+		return Device{}, err
+	}
+	for _, d := range devs {
+		if d.ID == defaultID {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("wpctl: no default sink found")
+}
+
+func (w *wpctlBackend) List(ctx context.Context) ([]Device, error) {
+	devs, _, err := w.listWithDefault(ctx)
+	return devs, err
+}
+
+func (w *wpctlBackend) Set(ctx context.Context, id string) error {
+	_, err := runCmd(ctx, "wpctl", "set-default", id)
+	return err
+}
+
+func (w *wpctlBackend) Watch(ctx context.Context) (<-chan DeviceEvent, error) {
+	return PollWatch(ctx, DefaultWatchInterval, w.List)
+}
+
+// wpctlTreeChars are the box-drawing characters wpctl prefixes every line
+// with to render its status tree (e.g. " ├─ Sinks:", " │      *   50. ...").
+// They survive strings.TrimSpace, so section/device matching has to strip
+// them explicitly first.
+const wpctlTreeChars = " \t├─│└╰╴"
+
+func stripWpctlTree(line string) string {
+	return strings.TrimSpace(strings.TrimLeft(line, wpctlTreeChars))
+}
+
+// listWithDefault parses `wpctl status`, which marks the default sink with a
+// leading "*" inside the "Sinks:" section, e.g.:
+//
+//	Sinks:
+//	 │  *   50. Built-in Audio Analog Stereo      [vol: 0.40]
+//	 │      63. AirPods Pro                       [vol: 1.00]
+func (w *wpctlBackend) listWithDefault(ctx context.Context) ([]Device, string, error) {
+	out, err := runCmd(ctx, "wpctl", "status")
+	if err != nil {
+		return nil, "", err
+	}
+	var devs []Device
+	var defaultID string
+	inSinks := false
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := stripWpctlTree(line)
+		switch {
+		case strings.HasPrefix(trimmed, "Sinks:"):
+			inSinks = true
+			continue
+		case inSinks && trimmed == "":
+			inSinks = false
+			continue
+		case inSinks && strings.Contains(trimmed, ":") && !strings.Contains(trimmed, "."):
+			inSinks = false
+			continue
+		}
+		if !inSinks {
+			continue
+		}
+		isDefault := strings.HasPrefix(trimmed, "*")
+		trimmed = strings.TrimPrefix(trimmed, "*")
+		trimmed = strings.TrimSpace(trimmed)
+		dot := strings.Index(trimmed, ".")
+		if dot <= 0 {
+			continue
+		}
+		id := strings.TrimSpace(trimmed[:dot])
+		rest := strings.TrimSpace(trimmed[dot+1:])
+		name := rest
+		if idx := strings.Index(rest, "["); idx >= 0 {
+			name = strings.TrimSpace(rest[:idx])
+		}
+		devs = append(devs, Device{ID: id, Name: name, Transport: "pipewire"})
+		if isDefault {
+			defaultID = id
+		}
+	}
+	return devs, defaultID, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	RegisterBackend("switchaudio", func(log *zap.SugaredLogger) AudioDevice {
+		return &switchAudioBackend{log: log}
+	})
+}
+
+// switchAudioBackend shells out to SwitchAudioSource (macOS only). It's the
+// original, cgo-free way to drive CoreAudio and remains the default backend
+// on darwin; see backend_coreaudio_darwin.go for the native alternative.
+type switchAudioBackend struct {
+	log *zap.SugaredLogger
+}
+
+func (s *switchAudioBackend) Dependencies() []string {
+	return []string{"SwitchAudioSource"}
+}
+
+func (s *switchAudioBackend) Get(ctx context.Context) (Device, error) {
+	out, err := runCmd(ctx, "SwitchAudioSource", "-c", "-t", "output")
+	if err != nil {
+		return Device{}, err
+	}
+	name := strings.TrimSpace(out)
+	return Device{ID: name, Name: name}, nil
+}
+
+func (s *switchAudioBackend) List(ctx context.Context) ([]Device, error) {
+	out, err := runCmd(ctx, "SwitchAudioSource", "-a", "-t", "output")
+	if err != nil {
+		return nil, err
+	}
+	var devs []Device
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		name := strings.TrimSpace(sc.Text())
+		if name != "" {
+			devs = append(devs, Device{ID: name, Name: name})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return devs, nil
+}
+
+func (s *switchAudioBackend) Set(ctx context.Context, id string) error {
+	_, err := runCmd(ctx, "SwitchAudioSource", "-s", id, "-t", "output")
+	return err
+}
+
+func (s *switchAudioBackend) Watch(ctx context.Context) (<-chan DeviceEvent, error) {
+	return PollWatch(ctx, DefaultWatchInterval, s.List)
+}
@@ -1,104 +1,367 @@
 package services
 
 import (
+	"context"
+	_ "embed"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/xeipuuv/gojsonschema"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 
 	"brianmargolis.com/audiout/utils"
 )
 
-// Config represents the application configuration
-type Config struct {
+// ConfigPathEnv is the environment variable checked first, and with the
+// highest priority, when resolving the config file. It's also the EnvVar
+// urfave/cli binds to the --config flag.
+const ConfigPathEnv = "AUDIOUT_CONFIG"
+
+// legacyConfigPath is the original ~/.config/.audiout.yaml location, kept
+// as the lowest-priority fallback so configs written before the XDG search
+// order keep working.
+const legacyConfigPath = "~/.config/.audiout.yaml"
+
+//go:embed config.schema.json
+var configSchema []byte
+
+// HostConfig overrides friendly names and ignore rules for a single remote
+// host, keyed by nickname (or hostname, if the peer has none) in
+// Config.Hosts.
+type HostConfig struct {
 	FriendlyNames map[string]string `yaml:"friendly"`
 	Ignored       []string          `yaml:"ignored"`
 }
 
-// ConfigService handles configuration loading and device name logic
-type ConfigService interface {
-	Load(path string) (*Config, error)
-	IsIgnored(deviceName string) bool
-	FriendlyName(deviceName string) string
-	BuildChoices(devices []string) []Choice
+// PriorityEntry is one entry in Config.Priority, matched against a device's
+// Name with filepath.Match semantics ("*" matches anything).
+type PriorityEntry struct {
+	Match string `yaml:"match"`
 }
 
-type configService struct {
-	config *Config
-	log    *zap.SugaredLogger
+// SwitchRule describes a single auto-switch reaction to a hotplug event. A
+// rule fires on WhenConnected (device name, matched on DeviceAdded) or
+// WhenDisconnected (matched on DeviceRemoved), and either switches to
+// SwitchTo by name or, on disconnect, falls back to the highest-priority
+// device still available.
+type SwitchRule struct {
+	WhenConnected            string `yaml:"when_connected"`
+	WhenDisconnected         string `yaml:"when_disconnected"`
+	SwitchTo                 string `yaml:"switch_to"`
+	SwitchToHighestAvailable bool   `yaml:"switch_to_highest_available"`
+}
+
+// Config is the application configuration, merged from every config file
+// found by ConfigSearchPaths.
+type Config struct {
+	FriendlyNames map[string]string     `yaml:"friendly"`
+	Ignored       []string              `yaml:"ignored"`
+	Nickname      string                `yaml:"nickname"`
+	Hosts         map[string]HostConfig `yaml:"hosts"`
+	Priority      []PriorityEntry       `yaml:"priority"`
+	Rules         []SwitchRule          `yaml:"rules"`
+
+	// Notify defaults to true when unset; use a pointer so "notify: false"
+	// in YAML is distinguishable from the key being absent.
+	Notify *bool `yaml:"notify"`
+	// Sound defaults to false when unset. Like Notify, it's a pointer so a
+	// higher-priority config layer can explicitly set "sound: false" to
+	// turn off a sound a lower-priority layer turned on — a plain bool's
+	// zero value can't be told apart from "unset" during merging.
+	Sound     *bool  `yaml:"sound"`
+	SoundFile string `yaml:"sound_file"`
+}
+
+// SoundEnabled reports whether the confirmation sound should play on
+// switch, defaulting to false when the config doesn't set sound.
+func (c *Config) SoundEnabled() bool {
+	return c.Sound != nil && *c.Sound
 }
 
-func NewConfigService(log *zap.SugaredLogger) ConfigService {
-	return &configService{
-		config: &Config{
-			FriendlyNames: map[string]string{},
-			Ignored:       []string{},
-		},
-		log: log,
+// NotifyEnabled reports whether desktop notifications should fire on
+// switch, defaulting to true when the config doesn't set notify.
+func (c *Config) NotifyEnabled() bool {
+	if c.Notify == nil {
+		return true
 	}
+	return *c.Notify
 }
 
-func (s *configService) Load(path string) (*Config, error) {
-	path = utils.ExpandPath(path)
-	b, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			s.log.Infow("config not found; using defaults", "path", path)
-			s.config = &Config{
-				FriendlyNames: map[string]string{},
-				Ignored:       []string{},
+// IsIgnored reports whether deviceName appears in the top-level ignore list.
+func (c *Config) IsIgnored(deviceName string) bool {
+	for _, n := range c.Ignored {
+		if deviceName == n {
+			return true
+		}
+	}
+	return false
+}
+
+// FriendlyName returns the configured friendly name for real, falling back
+// to real itself when no override is configured.
+func (c *Config) FriendlyName(real string) string {
+	if f, ok := c.FriendlyNames[real]; ok && f != "" {
+		return f
+	}
+	return real
+}
+
+// IsIgnoredForHost applies host's HostConfig overrides (Config.Hosts[host])
+// before falling back to the top-level ignore list used for local devices.
+func (c *Config) IsIgnoredForHost(deviceName, host string) bool {
+	if hc, ok := c.Hosts[host]; ok {
+		for _, n := range hc.Ignored {
+			if deviceName == n {
+				return true
 			}
-			return s.config, nil
 		}
-		return nil, err
 	}
+	return c.IsIgnored(deviceName)
+}
 
-	var config Config
-	if err := yaml.Unmarshal(b, &config); err != nil {
-		s.config = &Config{
-			FriendlyNames: map[string]string{},
-			Ignored:       []string{},
+// FriendlyNameForHost applies host's HostConfig overrides before falling
+// back to the top-level friendly name map used for local devices.
+func (c *Config) FriendlyNameForHost(real, host string) string {
+	if hc, ok := c.Hosts[host]; ok {
+		if f, ok := hc.FriendlyNames[real]; ok && f != "" {
+			return f
 		}
-		return s.config, err
 	}
+	return c.FriendlyName(real)
+}
 
-	if config.FriendlyNames == nil {
-		config.FriendlyNames = map[string]string{}
+// BuildChoices filters ignored devices out of devices and attaches each
+// survivor's friendly name, ready to hand to a Picker.
+func (c *Config) BuildChoices(devices []Device, log *zap.SugaredLogger) []Choice {
+	var choices []Choice
+	for _, d := range devices {
+		if c.IsIgnored(d.ID) {
+			log.Debugw("ignored device", "name", d.ID)
+			continue
+		}
+		choices = append(choices, Choice{FriendlyName: c.FriendlyName(d.ID), Device: d})
 	}
+	return choices
+}
 
-	s.config = &config
-	return s.config, nil
+// ConfigSearchPaths returns the ordered, highest-priority-first list of
+// paths checked for a config file. explicitPath (typically --config /
+// $AUDIOUT_CONFIG) wins outright; the rest follow the XDG base directory
+// convention, with the pre-XDG dotfile location kept as a last resort.
+func ConfigSearchPaths(explicitPath string) []string {
+	var paths []string
+	if explicitPath != "" {
+		paths = append(paths, explicitPath)
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "audiout", "config.yaml"))
+	}
+	paths = append(paths, "~/.config/audiout/config.yaml")
+	paths = append(paths, "/etc/audiout/config.yaml")
+	paths = append(paths, legacyConfigPath)
+	return paths
 }
 
-func (s *configService) IsIgnored(deviceName string) bool {
-	for _, ignored := range s.config.Ignored {
-		if deviceName == ignored {
-			return true
+// ConfigService resolves, loads, validates, and watches the audiout config
+// file(s).
+type ConfigService interface {
+	// Load searches ConfigSearchPaths and merges every file found, lowest
+	// priority first, so higher-priority files override individual keys
+	// rather than replacing the whole document.
+	Load() (*Config, error)
+	// Validate parses path against the embedded JSON schema and returns a
+	// descriptive error naming the offending field path (e.g.
+	// "(root).sound") instead of a bare yaml unmarshal failure. The
+	// generic-interface{} unmarshal this validates against discards YAML
+	// source positions, so errors are field-path only — no line/column.
+	Validate(path string) error
+	// Subscribe watches every path in ConfigSearchPaths for changes and
+	// pushes a freshly reloaded Config each time one changes, until ctx is
+	// done. The returned channel is closed when Subscribe stops watching.
+	Subscribe(ctx context.Context) (<-chan *Config, error)
+}
+
+type configService struct {
+	explicitPath string
+	log          *zap.SugaredLogger
+}
+
+func NewConfigService(explicitPath string, log *zap.SugaredLogger) ConfigService {
+	return &configService{explicitPath: explicitPath, log: log}
+}
+
+func (s *configService) Load() (*Config, error) {
+	merged := &Config{FriendlyNames: map[string]string{}}
+	paths := ConfigSearchPaths(s.explicitPath)
+	found := false
+	var loadErr error
+
+	for i := len(paths) - 1; i >= 0; i-- {
+		path := utils.ExpandPath(paths[i])
+		b, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				loadErr = fmt.Errorf("reading %s: %w", path, err)
+			}
+			continue
 		}
+		var layer Config
+		if err := yaml.Unmarshal(b, &layer); err != nil {
+			loadErr = fmt.Errorf("%s: %w", path, err)
+			continue
+		}
+		found = true
+		merged = mergeConfig(merged, &layer)
+		s.log.Debugw("config layer applied", "path", path)
 	}
-	return false
+
+	if !found {
+		s.log.Infow("no config found; using defaults", "searched", paths)
+	}
+	return merged, loadErr
 }
 
-func (s *configService) FriendlyName(deviceName string) string {
-	if friendlyName, ok := s.config.FriendlyNames[deviceName]; ok && friendlyName != "" {
-		return friendlyName
+// mergeConfig layers overlay on top of base: maps are merged key-by-key
+// (overlay wins on conflict), scalars and slices are replaced wholesale
+// when overlay sets them.
+func mergeConfig(base, overlay *Config) *Config {
+	merged := *base
+
+	if overlay.FriendlyNames != nil {
+		if merged.FriendlyNames == nil {
+			merged.FriendlyNames = map[string]string{}
+		}
+		for k, v := range overlay.FriendlyNames {
+			merged.FriendlyNames[k] = v
+		}
+	}
+	if overlay.Ignored != nil {
+		merged.Ignored = overlay.Ignored
 	}
-	return deviceName
+	if overlay.Nickname != "" {
+		merged.Nickname = overlay.Nickname
+	}
+	if overlay.Hosts != nil {
+		if merged.Hosts == nil {
+			merged.Hosts = map[string]HostConfig{}
+		}
+		for k, v := range overlay.Hosts {
+			merged.Hosts[k] = v
+		}
+	}
+	if overlay.Priority != nil {
+		merged.Priority = overlay.Priority
+	}
+	if overlay.Rules != nil {
+		merged.Rules = overlay.Rules
+	}
+	if overlay.Notify != nil {
+		merged.Notify = overlay.Notify
+	}
+	if overlay.Sound != nil {
+		merged.Sound = overlay.Sound
+	}
+	if overlay.SoundFile != "" {
+		merged.SoundFile = overlay.SoundFile
+	}
+	return &merged
 }
 
-func (s *configService) BuildChoices(devices []string) []Choice {
-	var choices []Choice
-	for _, device := range devices {
-		if s.IsIgnored(device) {
-			s.log.Debugw("ignored device", "name", device)
-			continue
+func (s *configService) Validate(path string) error {
+	path = utils.ExpandPath(path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewBytesLoader(configSchema),
+		gojsonschema.NewGoLoader(doc),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if !result.Valid() {
+		msgs := make([]string, 0, len(result.Errors()))
+		for _, e := range result.Errors() {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", e.Field(), e.Description()))
 		}
-		choices = append(choices, Choice{
-			FriendlyName: s.FriendlyName(device),
-			RealName:     device,
-		})
+		return fmt.Errorf("%s: %s", path, strings.Join(msgs, "; "))
 	}
-	return choices
+	return nil
+}
+
+func (s *configService) Subscribe(ctx context.Context) (<-chan *Config, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("config: fsnotify: %w", err)
+	}
+
+	var watched []string
+	for _, p := range ConfigSearchPaths(s.explicitPath) {
+		path := utils.ExpandPath(p)
+		// Watch the containing directory, not the file itself: editors
+		// typically replace the file (write-and-rename) rather than
+		// writing it in place, which an inode-based watch would miss.
+		if err := watcher.Add(filepath.Dir(path)); err == nil {
+			watched = append(watched, path)
+		}
+	}
+
+	ch := make(chan *Config)
+	go func() {
+		defer watcher.Close()
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isWatchedPath(event.Name, watched) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+				config, err := s.Load()
+				if err != nil {
+					s.log.Warnw("config reload failed; keeping previous config", "err", err)
+					continue
+				}
+				select {
+				case ch <- config:
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.log.Warnw("config watch error", "err", err)
+			}
+		}
+	}()
+	return ch, nil
 }
 
+func isWatchedPath(changed string, watched []string) bool {
+	for _, w := range watched {
+		if filepath.Clean(changed) == filepath.Clean(w) {
+			return true
+		}
+	}
+	return false
+}
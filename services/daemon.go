@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/grandcat/zeroconf"
+	"go.uber.org/zap"
+)
+
+// MDNSServiceType is the Zeroconf service type audiout daemons advertise
+// themselves under and audiout remote browses for.
+const MDNSServiceType = "_audiout._tcp"
+
+// DaemonConfig controls how Serve binds and advertises itself.
+type DaemonConfig struct {
+	Port     int
+	Nickname string
+}
+
+// Serve exposes audio's Get/List/Set operations over a small HTTP/JSON API
+// bound to cfg.Port and advertises the daemon over mDNS under
+// MDNSServiceType until ctx is cancelled.
+func Serve(ctx context.Context, audio AudioDevice, cfg DaemonConfig, log *zap.SugaredLogger) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+		d, err := audio.Get(r.Context())
+		writeJSON(w, d, err)
+	})
+	mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		devs, err := audio.List(r.Context())
+		writeJSON(w, devs, err)
+	})
+	mux.HandleFunc("/set", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID string `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err := audio.Set(r.Context(), req.ID)
+		writeJSON(w, struct{}{}, err)
+	})
+
+	addr := fmt.Sprintf(":%d", cfg.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("daemon: listen on %s: %w", addr, err)
+	}
+
+	instance := cfg.Nickname
+	if instance == "" {
+		instance = "audiout"
+	}
+	zcServer, err := zeroconf.Register(instance, MDNSServiceType, "local.", cfg.Port, []string{"nickname=" + cfg.Nickname}, nil)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("daemon: mdns register: %w", err)
+	}
+	defer zcServer.Shutdown()
+	log.Infow("daemon advertising", "service", MDNSServiceType, "port", cfg.Port, "nickname", cfg.Nickname)
+
+	server := &http.Server{Handler: mux}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Serve(listener) }()
+
+	select {
+	case <-ctx.Done():
+		log.Infow("daemon shutting down")
+		return server.Close()
+	case err := <-errCh:
+		return err
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// manualSwitchMarkerPath is a small sentinel file touched by `audiout
+// set`/`pick`/`toggle`/`remote` on every successful switch. A concurrently
+// running `audiout watch` process — a separate process, so it can't be
+// notified in-process — reads its mtime to debounce around switches the
+// user just made by hand.
+func manualSwitchMarkerPath() string {
+	return filepath.Join(os.TempDir(), "audiout-last-manual-switch")
+}
+
+// RecordManualSwitch touches the manual-switch marker file. It's
+// best-effort: a failure here only means a running `watch` might briefly
+// fight the user, not that the switch itself failed, so it only logs at
+// debug.
+func RecordManualSwitch(log *zap.SugaredLogger) {
+	path := manualSwitchMarkerPath()
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		f, err := os.Create(path)
+		if err != nil {
+			log.Debugw("failed to record manual switch marker", "err", err)
+			return
+		}
+		f.Close()
+	}
+}
+
+// lastManualSwitch returns when the marker file was last touched, or the
+// zero Time if no manual switch has been recorded.
+func lastManualSwitch() time.Time {
+	info, err := os.Stat(manualSwitchMarkerPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/gen2brain/beeep"
+	"go.uber.org/zap"
+)
+
+// NotifyOptions controls what Notify does after a successful switch.
+// SoundFile, when empty, plays the embedded default ding.
+type NotifyOptions struct {
+	Notify    bool
+	Sound     bool
+	SoundFile string
+}
+
+// Notify surfaces a successful device switch to the user: a desktop
+// notification and/or a short confirmation sound, per opts. Both are
+// best-effort — a failure here must never fail the switch itself, so errors
+// are only logged at warn.
+func Notify(opts NotifyOptions, friendlyName string, log *zap.SugaredLogger) {
+	if opts.Notify {
+		if err := beeep.Notify("audiout", fmt.Sprintf("Output → %s", friendlyName), ""); err != nil {
+			log.Warnw("desktop notification failed", "err", err)
+		}
+	}
+	if opts.Sound {
+		if err := playSound(opts.SoundFile); err != nil {
+			log.Warnw("confirmation sound failed", "err", err)
+		}
+	}
+}
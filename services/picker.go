@@ -11,18 +11,13 @@ import (
 	"go.uber.org/zap"
 )
 
-// Choice represents a device choice with real and friendly names
+// Choice represents a device choice with its underlying Device and the
+// friendly name it should be displayed under.
 type Choice struct {
-	RealName     string
+	Device       Device
 	FriendlyName string
 }
 
-// Config represents the application configuration
-type Config struct {
-	FriendlyNames map[string]string `yaml:"friendly"`
-	Ignored       []string          `yaml:"ignored"`
-}
-
 // Picker handles device selection logic
 type Picker interface {
 	PickDevice(ctx context.Context, choices []Choice, current string, toggleMode bool) (Choice, bool, error)
@@ -49,17 +44,17 @@ func (p *picker) toggleNext(choices []Choice, current string) (Choice, bool, err
 		return Choice{}, false, fmt.Errorf("no choices available")
 	}
 	
-	// Sort choices alphabetically by RealName
+	// Sort choices alphabetically by device name
 	sortedChoices := make([]Choice, len(choices))
 	copy(sortedChoices, choices)
 	sort.Slice(sortedChoices, func(i, j int) bool {
-		return sortedChoices[i].RealName < sortedChoices[j].RealName
+		return sortedChoices[i].Device.Name < sortedChoices[j].Device.Name
 	})
-	
+
 	// Find current device index
 	currentIdx := -1
 	for i, choice := range sortedChoices {
-		if choice.RealName == current {
+		if choice.Device.ID == current {
 			currentIdx = i
 			break
 		}
@@ -80,12 +75,16 @@ func (p *picker) toggleNext(choices []Choice, current string) (Choice, bool, err
 }
 
 func (p *picker) fzfPick(ctx context.Context, choices []Choice, current string) (Choice, bool, error) {
-	currentFriendly := friendlyOf(current, p.config)
-	
+	currentFriendly := p.config.FriendlyName(current)
+
 	var b strings.Builder
 	for _, c := range choices {
-		// FRIENDLY \t REAL
-		fmt.Fprintf(&b, "%s\t%s\n", c.FriendlyName, c.RealName)
+		rate := ""
+		if c.Device.SampleRate > 0 {
+			rate = fmt.Sprintf("%d Hz", c.Device.SampleRate)
+		}
+		// FRIENDLY \t TRANSPORT \t SAMPLE_RATE \t ID
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\n", c.FriendlyName, c.Device.Transport, rate, c.Device.ID)
 	}
 	input := b.String()
 
@@ -95,7 +94,7 @@ func (p *picker) fzfPick(ctx context.Context, choices []Choice, current string)
 		"--height", "40%",
 		"--reverse",
 		"--delimiter", "\t",
-		"--with-nth", "1",
+		"--with-nth", "1,2,3",
 		"--bind", "enter:accept",
 		"--exact",
 	}
@@ -122,16 +121,14 @@ func (p *picker) fzfPick(ctx context.Context, choices []Choice, current string)
 	if line == "" {
 		return Choice{}, false, nil
 	}
-	parts := strings.SplitN(line, "\t", 2)
-	if len(parts) != 2 {
+	parts := strings.SplitN(line, "\t", 4)
+	if len(parts) != 4 {
 		return Choice{}, false, fmt.Errorf("unexpected fzf line: %q", line)
 	}
-	return Choice{FriendlyName: parts[0], RealName: parts[1]}, true, nil
-}
-
-func friendlyOf(real string, config *Config) string {
-	if f, ok := config.FriendlyNames[real]; ok && f != "" {
-		return f
+	for _, choice := range choices {
+		if choice.Device.ID == parts[3] {
+			return choice, true, nil
+		}
 	}
-	return real
+	return Choice{}, false, fmt.Errorf("fzf returned unknown device id: %q", parts[3])
 }
\ No newline at end of file
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grandcat/zeroconf"
+	"go.uber.org/zap"
+)
+
+// Peer describes an audiout daemon discovered on the local network via mDNS.
+type Peer struct {
+	// Host is the mDNS-advertised hostname (e.g. "My-Mac.local."), kept for
+	// display purposes only.
+	Host string
+	// Addr is the resolved IPv4/IPv6 address to actually connect to. Go's
+	// default resolver doesn't do mDNS resolution for .local names outside
+	// of cgo-resolver/nss-mdns setups, so Host alone isn't reliably
+	// dialable — Addr is what zeroconf already resolved during the browse.
+	Addr     string
+	Port     int
+	Nickname string
+}
+
+// DiscoverPeers browses mDNS for MDNSServiceType and returns whatever peers
+// answer before timeout elapses.
+func DiscoverPeers(ctx context.Context, timeout time.Duration, log *zap.SugaredLogger) ([]Peer, error) {
+	resolver, err := zeroconf.NewResolver(nil)
+	if err != nil {
+		return nil, fmt.Errorf("remote: mdns resolver: %w", err)
+	}
+
+	entries := make(chan *zeroconf.ServiceEntry)
+	var peers []Peer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for entry := range entries {
+			p := Peer{Host: entry.HostName, Port: entry.Port}
+			switch {
+			case len(entry.AddrIPv4) > 0:
+				p.Addr = entry.AddrIPv4[0].String()
+			case len(entry.AddrIPv6) > 0:
+				p.Addr = entry.AddrIPv6[0].String()
+			}
+			for _, txt := range entry.Text {
+				if n, ok := strings.CutPrefix(txt, "nickname="); ok {
+					p.Nickname = n
+				}
+			}
+			peers = append(peers, p)
+		}
+	}()
+
+	browseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := resolver.Browse(browseCtx, MDNSServiceType, "local.", entries); err != nil {
+		return nil, fmt.Errorf("remote: mdns browse: %w", err)
+	}
+	<-browseCtx.Done()
+	<-done
+	log.Debugw("mdns discovery complete", "peers", len(peers))
+	return peers, nil
+}
+
+// RemoteAudioDevice forwards AudioDevice operations to a peer's audiout
+// serve HTTP/JSON API.
+type RemoteAudioDevice struct {
+	baseURL string
+	client  *http.Client
+	log     *zap.SugaredLogger
+}
+
+func NewRemoteAudioDevice(peer Peer, log *zap.SugaredLogger) *RemoteAudioDevice {
+	addr := peer.Addr
+	if addr == "" {
+		addr = peer.Host
+	}
+	return &RemoteAudioDevice{
+		baseURL: fmt.Sprintf("http://%s", net.JoinHostPort(addr, strconv.Itoa(peer.Port))),
+		client:  &http.Client{Timeout: 5 * time.Second},
+		log:     log,
+	}
+}
+
+func (r *RemoteAudioDevice) Dependencies() []string { return nil }
+
+func (r *RemoteAudioDevice) Get(ctx context.Context) (Device, error) {
+	var d Device
+	err := r.getJSON(ctx, "/get", &d)
+	return d, err
+}
+
+func (r *RemoteAudioDevice) List(ctx context.Context) ([]Device, error) {
+	var devs []Device
+	err := r.getJSON(ctx, "/list", &devs)
+	return devs, err
+}
+
+func (r *RemoteAudioDevice) Set(ctx context.Context, id string) error {
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/set", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote: set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote: set: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (r *RemoteAudioDevice) Watch(ctx context.Context) (<-chan DeviceEvent, error) {
+	return PollWatch(ctx, DefaultWatchInterval, r.List)
+}
+
+func (r *RemoteAudioDevice) getJSON(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote: %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote: %s: status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
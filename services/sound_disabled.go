@@ -0,0 +1,13 @@
+//go:build !audiout_sound
+
+package services
+
+import "fmt"
+
+// playSound is the default (non-audiout_sound-tagged) build: this binary
+// was built without faiface/beep, so `sound: true` in config is a no-op
+// that logs a warning rather than a hard requirement on cgo/alsa-dev. See
+// sound_enabled.go for the real implementation.
+func playSound(path string) error {
+	return fmt.Errorf("sound playback not compiled into this binary (rebuild with -tags audiout_sound)")
+}
@@ -0,0 +1,51 @@
+//go:build audiout_sound
+
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+
+	"brianmargolis.com/audiout/utils"
+)
+
+//go:embed assets/ding.wav
+var defaultDing []byte
+
+// playSound is only compiled in when built with -tags audiout_sound: it
+// pulls in faiface/beep, which in turn pulls in hajimehoshi/oto, which
+// requires cgo and (on Linux) libasound2-dev. Most installs only need
+// desktop notifications, so sound playback is opt-in at build time rather
+// than a hard dependency of every audiout binary.
+func playSound(path string) error {
+	data := defaultDing
+	if path != "" {
+		b, err := os.ReadFile(utils.ExpandPath(path))
+		if err != nil {
+			return fmt.Errorf("reading sound_file: %w", err)
+		}
+		data = b
+	}
+
+	streamer, format, err := wav.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decoding sound: %w", err)
+	}
+	defer streamer.Close()
+
+	if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
+		return fmt.Errorf("initializing speaker: %w", err)
+	}
+
+	done := make(chan struct{})
+	speaker.Play(beep.Seq(streamer, beep.Callback(func() { close(done) })))
+	<-done
+	return nil
+}